@@ -0,0 +1,270 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/segmentio/kafka-go"
+	"github.com/streadway/amqp"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Item lifecycle events published alongside a create/update/delete.
+const (
+	ItemEventCreated = "item.created"
+	ItemEventUpdated = "item.updated"
+	ItemEventDeleted = "item.deleted"
+)
+
+// createItemIndexes ensures the indexes getStats and item lookups rely on exist, so
+// those queries stay fast as the items collection grows.
+func createItemIndexes(ctx context.Context) error {
+	_, err := itemCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "name", Value: 1}}},
+		{Keys: bson.D{{Key: "category", Value: 1}}},
+		{Keys: bson.D{{Key: "createdAt", Value: -1}}},
+	})
+	return err
+}
+
+// emitItemEvent publishes an item lifecycle event to the go-events Kafka topic and the
+// items_queue, tagged with the request ID from ctx so it can be correlated back to the
+// HTTP request that caused it.
+func emitItemEvent(ctx context.Context, event string, item Item) {
+	payload := map[string]interface{}{
+		"event":     event,
+		"data":      item,
+		"timestamp": time.Now().Format(time.RFC3339),
+	}
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		FromContext(ctx).WithError(err).Errorf("Error marshaling %s event", event)
+		return
+	}
+
+	requestID := requestIDFromContext(ctx)
+
+	kafkaStatus := "success"
+	if err := kafkaWriter.WriteMessages(context.Background(), kafka.Message{
+		Value:   payloadBytes,
+		Headers: []kafka.Header{{Key: requestIDHeader, Value: []byte(requestID)}},
+	}); err != nil {
+		kafkaStatus = "error"
+		FromContext(ctx).WithError(err).Errorf("Error publishing %s to Kafka", event)
+	}
+	kafkaMessagesProducedTotal.WithLabelValues("go-events", kafkaStatus).Inc()
+
+	publishToQueue("items_queue", amqp.Publishing{
+		ContentType: "application/json",
+		Headers:     amqp.Table{requestIDHeader: requestID},
+		Body:        payloadBytes,
+	})
+}
+
+func createItem(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	var item Item
+	if err := c.ShouldBindJSON(&item); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request body",
+		})
+		return
+	}
+
+	item.ID = primitive.NewObjectID()
+	item.CreatedAt = time.Now()
+	item.UpdatedAt = item.CreatedAt
+
+	err := observeMongoOp("items", "insertOne", func() error {
+		_, insertErr := itemCollection.InsertOne(ctx, item)
+		return insertErr
+	})
+	if err != nil {
+		FromContext(ctx).WithError(err).Error("Failed to create item")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create item",
+		})
+		return
+	}
+
+	emitItemEvent(ctx, ItemEventCreated, item)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    item,
+	})
+}
+
+func updateItem(c *gin.Context) {
+	id := c.Param("id")
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid item ID",
+		})
+		return
+	}
+
+	var update Item
+	if err := c.ShouldBindJSON(&update); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request body",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	update.UpdatedAt = time.Now()
+
+	var item Item
+	err = observeMongoOp("items", "findOneAndUpdate", func() error {
+		return itemCollection.FindOneAndUpdate(
+			ctx,
+			bson.M{"_id": objectID},
+			bson.M{"$set": bson.M{
+				"name":        update.Name,
+				"description": update.Description,
+				"price":       update.Price,
+				"category":    update.Category,
+				"updatedAt":   update.UpdatedAt,
+			}},
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&item)
+	})
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Item not found",
+			})
+			return
+		}
+		FromContext(ctx).WithError(err).Error("Failed to update item")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to update item",
+		})
+		return
+	}
+
+	emitItemEvent(ctx, ItemEventUpdated, item)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    item,
+	})
+}
+
+func deleteItem(c *gin.Context) {
+	id := c.Param("id")
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid item ID",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	var item Item
+	err = observeMongoOp("items", "findOneAndDelete", func() error {
+		return itemCollection.FindOneAndDelete(ctx, bson.M{"_id": objectID}).Decode(&item)
+	})
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Item not found",
+			})
+			return
+		}
+		FromContext(ctx).WithError(err).Error("Failed to delete item")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to delete item",
+		})
+		return
+	}
+
+	emitItemEvent(ctx, ItemEventDeleted, item)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Item deleted successfully",
+	})
+}
+
+// itemFromRabbitAction decodes the Item carried by a create/update RabbitMessage and
+// resolves its destination ObjectID from msg.ItemID, independent of Mongo, so the
+// decoding logic can be unit tested without a live collection.
+func itemFromRabbitAction(msg RabbitMessage) (Item, error) {
+	itemBytes, err := json.Marshal(msg.Item)
+	if err != nil {
+		return Item{}, err
+	}
+
+	var item Item
+	if err := json.Unmarshal(itemBytes, &item); err != nil {
+		return Item{}, err
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(msg.ItemID)
+	if err != nil {
+		return Item{}, err
+	}
+	item.ID = objectID
+	item.UpdatedAt = time.Now()
+	if item.CreatedAt.IsZero() {
+		item.CreatedAt = item.UpdatedAt
+	}
+
+	return item, nil
+}
+
+// applyRabbitItemAction applies a create/update/delete action received from another
+// service via RabbitMessage.Action. It is idempotent by ItemID: creating/updating an
+// item that doesn't exist yet upserts it, and deleting an item that is already gone is
+// not an error, so redelivery never produces a different outcome.
+func applyRabbitItemAction(ctx context.Context, msg RabbitMessage) error {
+	logger := FromContext(ctx)
+
+	if msg.Action == "delete" {
+		objectID, err := primitive.ObjectIDFromHex(msg.ItemID)
+		if err != nil {
+			return err
+		}
+		return observeMongoOp("items", "deleteOne", func() error {
+			_, deleteErr := itemCollection.DeleteOne(ctx, bson.M{"_id": objectID})
+			return deleteErr
+		})
+	}
+
+	item, err := itemFromRabbitAction(msg)
+	if err != nil {
+		return err
+	}
+
+	switch msg.Action {
+	case "create", "update":
+		return observeMongoOp("items", "replaceOne", func() error {
+			_, replaceErr := itemCollection.ReplaceOne(
+				ctx,
+				bson.M{"_id": item.ID},
+				item,
+				options.Replace().SetUpsert(true),
+			)
+			return replaceErr
+		})
+	default:
+		logger.Warnf("Ignoring RabbitMQ message with unknown action: %s", msg.Action)
+		return nil
+	}
+}
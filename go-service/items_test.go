@@ -0,0 +1,77 @@
+package main
+
+import "testing"
+
+func TestItemFromRabbitAction(t *testing.T) {
+	const validID = "507f1f77bcf86cd799439011"
+
+	cases := []struct {
+		name    string
+		msg     RabbitMessage
+		wantErr bool
+	}{
+		{
+			name: "valid create decodes fields and defaults CreatedAt",
+			msg: RabbitMessage{
+				Action: "create",
+				ItemID: validID,
+				Item: map[string]interface{}{
+					"name":     "Widget",
+					"category": "tools",
+					"price":    9.99,
+				},
+			},
+		},
+		{
+			name: "valid update decodes fields",
+			msg: RabbitMessage{
+				Action: "update",
+				ItemID: validID,
+				Item: map[string]interface{}{
+					"name":     "Widget v2",
+					"category": "tools",
+				},
+			},
+		},
+		{
+			name: "invalid item id errors",
+			msg: RabbitMessage{
+				Action: "update",
+				ItemID: "not-an-object-id",
+				Item:   map[string]interface{}{"name": "Widget"},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			item, err := itemFromRabbitAction(tc.msg)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("itemFromRabbitAction() error = nil, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("itemFromRabbitAction() unexpected error: %v", err)
+			}
+			if item.ID.Hex() != tc.msg.ItemID {
+				t.Errorf("item.ID = %s, want %s", item.ID.Hex(), tc.msg.ItemID)
+			}
+			fields := tc.msg.Item.(map[string]interface{})
+			if want, _ := fields["name"].(string); item.Name != want {
+				t.Errorf("item.Name = %q, want %q", item.Name, want)
+			}
+			if want, _ := fields["category"].(string); item.Category != want {
+				t.Errorf("item.Category = %q, want %q", item.Category, want)
+			}
+			if item.CreatedAt.IsZero() {
+				t.Error("item.CreatedAt should default to now when absent from the payload")
+			}
+			if item.UpdatedAt.IsZero() {
+				t.Error("item.UpdatedAt should always be set")
+			}
+		})
+	}
+}
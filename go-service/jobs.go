@@ -0,0 +1,459 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/segmentio/kafka-go"
+	"github.com/streadway/amqp"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Job status values, mirroring the R1 PM-job ENABLED/DISABLED/ERROR lifecycle.
+const (
+	JobStatusEnabled  = "ENABLED"
+	JobStatusDisabled = "DISABLED"
+	JobStatusError    = "ERROR"
+)
+
+// Delivery targets supported by a job's DeliveryInfo.
+const (
+	DeliveryTargetKafka   = "kafka"
+	DeliveryTargetRabbit  = "rabbit"
+	DeliveryTargetWebhook = "webhook"
+)
+
+// JobFilter narrows which messages a job wants republished. An empty field means
+// "match anything" for that dimension.
+type JobFilter struct {
+	Source           string   `json:"source,omitempty" bson:"source,omitempty"`
+	MeasuredObject   string   `json:"measuredObject,omitempty" bson:"measuredObject,omitempty"`
+	CounterAllowList []string `json:"counterAllowList,omitempty" bson:"counterAllowList,omitempty"`
+}
+
+// DeliveryInfo names where matching messages get republished.
+type DeliveryInfo struct {
+	Target string `json:"target" bson:"target"`
+	Topic  string `json:"topic,omitempty" bson:"topic,omitempty"`
+	Queue  string `json:"queue,omitempty" bson:"queue,omitempty"`
+	URL    string `json:"url,omitempty" bson:"url,omitempty"`
+}
+
+// JobDefinition is a user-defined subscription: deliver messages matching Filter,
+// for the given InfoTypeID, to DeliveryInfo's target.
+type JobDefinition struct {
+	ID                    primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	InfoTypeID            string             `json:"infoTypeId" bson:"infoTypeId"`
+	Filter                JobFilter          `json:"filter" bson:"filter"`
+	DeliveryInfo          DeliveryInfo       `json:"deliveryInfo" bson:"deliveryInfo"`
+	StatusNotificationURI string             `json:"statusNotificationUri,omitempty" bson:"statusNotificationUri,omitempty"`
+	Status                string             `json:"status" bson:"status"`
+	CreatedAt             time.Time          `json:"createdAt" bson:"createdAt"`
+	UpdatedAt             time.Time          `json:"updatedAt" bson:"updatedAt"`
+}
+
+var (
+	jobCollection  *mongo.Collection
+	jobKafkaWriter *kafka.Writer
+
+	jobsMu         sync.RWMutex
+	jobsByInfoType = make(map[string][]*JobDefinition)
+)
+
+// jobHTTPClient is used for webhook delivery and status notification POSTs. A bounded
+// timeout keeps a slow/unresponsive webhook target from hanging the caller forever.
+var jobHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// indexJob adds or replaces job in the in-memory index, keyed by InfoTypeID so
+// matching a message against active jobs stays O(number of relevant jobs).
+func indexJob(job *JobDefinition) {
+	jobsMu.Lock()
+	defer jobsMu.Unlock()
+
+	jobs := jobsByInfoType[job.InfoTypeID]
+	for i, existing := range jobs {
+		if existing.ID == job.ID {
+			jobs[i] = job
+			jobsByInfoType[job.InfoTypeID] = jobs
+			return
+		}
+	}
+	jobsByInfoType[job.InfoTypeID] = append(jobs, job)
+}
+
+// unindexJob removes a job from the in-memory index.
+func unindexJob(infoTypeID string, id primitive.ObjectID) {
+	jobsMu.Lock()
+	defer jobsMu.Unlock()
+
+	jobs := jobsByInfoType[infoTypeID]
+	for i, existing := range jobs {
+		if existing.ID == id {
+			jobsByInfoType[infoTypeID] = append(jobs[:i], jobs[i+1:]...)
+			return
+		}
+	}
+}
+
+// matchingJobs returns the enabled jobs subscribed to infoTypeID.
+func matchingJobs(infoTypeID string) []*JobDefinition {
+	jobsMu.RLock()
+	defer jobsMu.RUnlock()
+
+	jobs := jobsByInfoType[infoTypeID]
+	matched := make([]*JobDefinition, 0, len(jobs))
+	for _, job := range jobs {
+		if job.Status == JobStatusEnabled {
+			matched = append(matched, job)
+		}
+	}
+	return matched
+}
+
+// loadJobIndex rebuilds the in-memory job index from Mongo. Called once at startup so a
+// service restart picks up jobs created in a previous run.
+func loadJobIndex(ctx context.Context) error {
+	cursor, err := jobCollection.Find(ctx, bson.M{})
+	if err != nil {
+		return fmt.Errorf("failed to load jobs: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var jobs []*JobDefinition
+	if err := cursor.All(ctx, &jobs); err != nil {
+		return fmt.Errorf("failed to decode jobs: %w", err)
+	}
+
+	jobsMu.Lock()
+	jobsByInfoType = make(map[string][]*JobDefinition)
+	jobsMu.Unlock()
+
+	for _, job := range jobs {
+		indexJob(job)
+	}
+
+	log.Printf("Loaded %d job(s) into the filter index", len(jobs))
+	return nil
+}
+
+// matchFilter reports whether payload satisfies filter. A zero-value field on the
+// filter is treated as "don't care" for that dimension.
+func matchFilter(filter JobFilter, payload map[string]interface{}) bool {
+	if filter.Source != "" {
+		if source, _ := payload["source"].(string); source != filter.Source {
+			return false
+		}
+	}
+
+	if filter.MeasuredObject != "" {
+		if measuredObject, _ := payload["measuredObject"].(string); measuredObject != filter.MeasuredObject {
+			return false
+		}
+	}
+
+	if len(filter.CounterAllowList) > 0 {
+		counter, _ := payload["counter"].(string)
+		allowed := false
+		for _, c := range filter.CounterAllowList {
+			if c == counter {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return false
+		}
+	}
+
+	return true
+}
+
+// dispatchToJobs evaluates every job subscribed to infoTypeID against data and republishes
+// matches to each job's delivery target.
+func dispatchToJobs(infoTypeID string, data interface{}) {
+	jobs := matchingJobs(infoTypeID)
+	if len(jobs) == 0 {
+		return
+	}
+
+	payload, _ := data.(map[string]interface{})
+	payloadBytes, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("Error marshaling payload for job dispatch: %v", err)
+		return
+	}
+
+	for _, job := range jobs {
+		if !matchFilter(job.Filter, payload) {
+			continue
+		}
+		if err := deliverJobPayload(job, payloadBytes); err != nil {
+			log.Printf("Error delivering job %s payload: %v", job.ID.Hex(), err)
+			setJobStatus(job, JobStatusError)
+		}
+	}
+}
+
+// deliverJobPayload republishes payload to job's configured delivery target. Webhook
+// delivery is dispatched onto its own goroutine rather than done inline: dispatchToJobs
+// runs synchronously on the RabbitMQ/Kafka consumer's hot path, and a slow or
+// unresponsive webhook target must not stall ingestion for every other job.
+func deliverJobPayload(job *JobDefinition, payload []byte) error {
+	switch job.DeliveryInfo.Target {
+	case DeliveryTargetKafka:
+		return jobKafkaWriter.WriteMessages(context.Background(), kafka.Message{
+			Topic: job.DeliveryInfo.Topic,
+			Value: payload,
+		})
+	case DeliveryTargetRabbit:
+		return publishToQueue(job.DeliveryInfo.Queue, amqp.Publishing{
+			ContentType: "application/json",
+			Body:        payload,
+		})
+	case DeliveryTargetWebhook:
+		go deliverWebhookPayload(job, payload)
+		return nil
+	default:
+		return fmt.Errorf("unsupported delivery target: %s", job.DeliveryInfo.Target)
+	}
+}
+
+// deliverWebhookPayload POSTs payload to job's webhook URL off the caller's hot path,
+// marking the job ERROR itself on failure since its caller has already returned.
+func deliverWebhookPayload(job *JobDefinition, payload []byte) {
+	resp, err := jobHTTPClient.Post(job.DeliveryInfo.URL, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		log.Printf("Error delivering job %s payload: %v", job.ID.Hex(), err)
+		setJobStatus(job, JobStatusError)
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		log.Printf("Error delivering job %s payload: webhook %s returned status %d", job.ID.Hex(), job.DeliveryInfo.URL, resp.StatusCode)
+		setJobStatus(job, JobStatusError)
+	}
+}
+
+// setJobStatus persists a job status transition and notifies StatusNotificationURI, if
+// set. The Status/UpdatedAt mutation is guarded by jobsMu (the same lock matchingJobs
+// reads job.Status under) since up to KafkaMaxInflight goroutines can call this
+// concurrently for the same job, e.g. one whose webhook target is consistently failing.
+func setJobStatus(job *JobDefinition, status string) {
+	jobsMu.Lock()
+	if job.Status == status {
+		jobsMu.Unlock()
+		return
+	}
+	job.Status = status
+	job.UpdatedAt = time.Now()
+	jobsMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	observeMongoOp("jobs", "updateOne", func() error {
+		_, err := jobCollection.UpdateOne(ctx, bson.M{"_id": job.ID}, bson.M{
+			"$set": bson.M{"status": status, "updatedAt": job.UpdatedAt},
+		})
+		return err
+	})
+
+	indexJob(job)
+	notifyJobStatus(job)
+}
+
+// notifyJobStatus pushes the job's current status to its status_notification_uri, if configured.
+func notifyJobStatus(job *JobDefinition) {
+	if job.StatusNotificationURI == "" {
+		return
+	}
+
+	body, _ := json.Marshal(gin.H{
+		"jobId":  job.ID.Hex(),
+		"status": job.Status,
+	})
+
+	resp, err := jobHTTPClient.Post(job.StatusNotificationURI, "application/json", bytes.NewReader(body))
+	if err != nil {
+		log.Printf("Error notifying job %s status: %v", job.ID.Hex(), err)
+		return
+	}
+	resp.Body.Close()
+}
+
+// validateWebhookTarget rejects URLs that POST /api/jobs shouldn't be allowed to make
+// this service call out to: DeliveryInfo.URL and StatusNotificationURI come straight
+// from the request body, and deliverWebhookPayload/notifyJobStatus POST to them
+// unconditionally, which is an SSRF primitive against internal services, loopback
+// addresses, and cloud metadata endpoints unless the target is restricted up front.
+func validateWebhookTarget(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid URL: %w", err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return fmt.Errorf("unsupported URL scheme: %q", parsed.Scheme)
+	}
+
+	host := parsed.Hostname()
+	if host == "" {
+		return fmt.Errorf("URL must include a host")
+	}
+
+	ips, err := net.LookupIP(host)
+	if err != nil {
+		return fmt.Errorf("failed to resolve host %q: %w", host, err)
+	}
+	for _, ip := range ips {
+		if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+			ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() {
+			return fmt.Errorf("URL host %q resolves to a disallowed address %s", host, ip)
+		}
+	}
+
+	return nil
+}
+
+// Job REST handlers
+
+func createJob(c *gin.Context) {
+	var job JobDefinition
+	if err := c.ShouldBindJSON(&job); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request body",
+		})
+		return
+	}
+
+	if job.DeliveryInfo.Target == DeliveryTargetWebhook {
+		if err := validateWebhookTarget(job.DeliveryInfo.URL); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("invalid deliveryInfo.url: %v", err),
+			})
+			return
+		}
+	}
+	if job.StatusNotificationURI != "" {
+		if err := validateWebhookTarget(job.StatusNotificationURI); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("invalid statusNotificationUri: %v", err),
+			})
+			return
+		}
+	}
+
+	job.ID = primitive.NewObjectID()
+	job.Status = JobStatusEnabled
+	job.CreatedAt = time.Now()
+	job.UpdatedAt = job.CreatedAt
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	err := observeMongoOp("jobs", "insertOne", func() error {
+		_, insertErr := jobCollection.InsertOne(ctx, job)
+		return insertErr
+	})
+	if err != nil {
+		FromContext(ctx).WithError(err).Error("Failed to create job")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create job",
+		})
+		return
+	}
+
+	indexJob(&job)
+	FromContext(ctx).Infof("Created job %s for info type %s", job.ID.Hex(), job.InfoTypeID)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    job,
+	})
+}
+
+func getJob(c *gin.Context) {
+	id := c.Param("id")
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid job ID",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	var job JobDefinition
+	err = observeMongoOp("jobs", "findOne", func() error {
+		return jobCollection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&job)
+	})
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Job not found",
+			})
+			return
+		}
+		FromContext(ctx).WithError(err).Error("Failed to fetch job")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch job",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    job,
+	})
+}
+
+func deleteJob(c *gin.Context) {
+	id := c.Param("id")
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid job ID",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	defer cancel()
+
+	var job JobDefinition
+	err = observeMongoOp("jobs", "findOneAndDelete", func() error {
+		return jobCollection.FindOneAndDelete(ctx, bson.M{"_id": objectID}).Decode(&job)
+	})
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Job not found",
+			})
+			return
+		}
+		FromContext(ctx).WithError(err).Error("Failed to delete job")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to delete job",
+		})
+		return
+	}
+
+	unindexJob(job.InfoTypeID, job.ID)
+	FromContext(ctx).Infof("Deleted job %s", job.ID.Hex())
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Job deleted successfully",
+	})
+}
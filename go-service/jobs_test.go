@@ -0,0 +1,69 @@
+package main
+
+import "testing"
+
+func TestMatchFilter(t *testing.T) {
+	cases := []struct {
+		name    string
+		filter  JobFilter
+		payload map[string]interface{}
+		want    bool
+	}{
+		{
+			name:    "empty filter matches anything",
+			filter:  JobFilter{},
+			payload: map[string]interface{}{"source": "sensor-1"},
+			want:    true,
+		},
+		{
+			name:    "source filter matches",
+			filter:  JobFilter{Source: "sensor-1"},
+			payload: map[string]interface{}{"source": "sensor-1"},
+			want:    true,
+		},
+		{
+			name:    "source filter rejects mismatch",
+			filter:  JobFilter{Source: "sensor-1"},
+			payload: map[string]interface{}{"source": "sensor-2"},
+			want:    false,
+		},
+		{
+			name:    "source filter rejects missing field",
+			filter:  JobFilter{Source: "sensor-1"},
+			payload: map[string]interface{}{},
+			want:    false,
+		},
+		{
+			name:    "counter allow list matches",
+			filter:  JobFilter{CounterAllowList: []string{"a", "b"}},
+			payload: map[string]interface{}{"counter": "b"},
+			want:    true,
+		},
+		{
+			name:    "counter allow list rejects unlisted counter",
+			filter:  JobFilter{CounterAllowList: []string{"a", "b"}},
+			payload: map[string]interface{}{"counter": "c"},
+			want:    false,
+		},
+		{
+			name: "all set dimensions must match",
+			filter: JobFilter{
+				Source:         "sensor-1",
+				MeasuredObject: "temperature",
+			},
+			payload: map[string]interface{}{
+				"source":         "sensor-1",
+				"measuredObject": "humidity",
+			},
+			want: false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := matchFilter(tc.filter, tc.payload); got != tc.want {
+				t.Errorf("matchFilter(%+v, %+v) = %v, want %v", tc.filter, tc.payload, got, tc.want)
+			}
+		})
+	}
+}
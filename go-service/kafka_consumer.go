@@ -0,0 +1,255 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// kafkaConsumerFanout is the number of readers joining the "go-service-group" consumer
+// group; Kafka's group coordinator spreads item-events' partitions across them so a slow
+// partition never stalls the others.
+const kafkaConsumerFanout = 4
+
+// kafkaMaxProcessAttempts is how many times processing a message is attempted (with a
+// backoff sleep after each failed attempt) before it is routed to the dead-letter topic.
+const kafkaMaxProcessAttempts = 3
+
+var kafkaPartitionReaders []*kafka.Reader
+
+// dltKafkaWriter publishes dead-lettered messages. Its Topic is left unset (unlike
+// kafkaWriter, whose Topic is "go-events") because kafka-go's Writer rejects a message
+// that sets Message.Topic when the Writer itself already has one configured, and
+// sendToDeadLetterTopic needs to route each message to config.KafkaDLTTopic.
+var dltKafkaWriter *kafka.Writer
+
+// partitionCommitTracker enforces in-order commits for a single partition. Messages
+// fetched from a reader are handled by independent goroutines bounded only by
+// parallelismLimiter, so they can finish out of fetch order; committing whichever
+// offset finishes first would let kafka-go's CommitMessages (which commits "up through"
+// the given offset) silently skip an earlier offset that's still retrying or being
+// dead-lettered if the process crashes right after. track/complete instead only ever
+// surface an offset to commit once every earlier-fetched offset on the partition has
+// also finished.
+type partitionCommitTracker struct {
+	mu       sync.Mutex
+	queue    []int64
+	finished map[int64]bool
+}
+
+func newPartitionCommitTracker() *partitionCommitTracker {
+	return &partitionCommitTracker{finished: make(map[int64]bool)}
+}
+
+// track records offset as in-flight. Callers must call this for every fetched offset,
+// in fetch order, before handling it on another goroutine.
+func (t *partitionCommitTracker) track(offset int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.queue = append(t.queue, offset)
+}
+
+// complete marks offset as finished and returns the highest offset that is now safe to
+// commit, i.e. the end of the contiguous run of finished offsets at the front of the
+// queue. ok is false if an earlier-fetched offset on this partition hasn't finished yet.
+func (t *partitionCommitTracker) complete(offset int64) (commitOffset int64, ok bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.finished[offset] = true
+
+	for len(t.queue) > 0 && t.finished[t.queue[0]] {
+		commitOffset = t.queue[0]
+		ok = true
+		delete(t.finished, t.queue[0])
+		t.queue = t.queue[1:]
+	}
+	return commitOffset, ok
+}
+
+// readerCommitState holds a partitionCommitTracker per partition currently assigned to
+// one reader, created lazily as new partitions are seen (e.g. after a rebalance).
+type readerCommitState struct {
+	mu         sync.Mutex
+	partitions map[int]*partitionCommitTracker
+}
+
+func newReaderCommitState() *readerCommitState {
+	return &readerCommitState{partitions: make(map[int]*partitionCommitTracker)}
+}
+
+func (s *readerCommitState) trackerFor(partition int) *partitionCommitTracker {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tracker, ok := s.partitions[partition]
+	if !ok {
+		tracker = newPartitionCommitTracker()
+		s.partitions[partition] = tracker
+	}
+	return tracker
+}
+
+// startKafkaConsumer fans the partition-aware readers created by connectKafka out onto
+// a bounded worker pool (parallelismLimiter) so a slow processKafkaMessage call stalls
+// at most one in-flight message per worker slot, not the whole poll loop.
+func startKafkaConsumer(config *Config) {
+	parallelismLimiter := make(chan struct{}, config.KafkaMaxInflight)
+
+	for _, reader := range kafkaPartitionReaders {
+		reader := reader
+		state := newReaderCommitState()
+		// FetchMessage never commits on its own; in "auto" commit mode ReadMessage is used
+		// instead, which hands each offset to the reader's CommitInterval timer.
+		fetch := reader.FetchMessage
+		if config.KafkaCommitMode == "auto" {
+			fetch = reader.ReadMessage
+		}
+		go func() {
+			for {
+				message, err := fetch(context.Background())
+				if err != nil {
+					baseLogger.WithError(err).Error("Error fetching Kafka message")
+					kafkaMessagesConsumedTotal.WithLabelValues("item-events", "error").Inc()
+					continue
+				}
+
+				// Tracked here, on the single fetch goroutine, so offsets are recorded in
+				// the order they were actually fetched before handling fans out.
+				state.trackerFor(message.Partition).track(message.Offset)
+
+				parallelismLimiter <- struct{}{}
+				kafkaConsumeInFlight.Inc()
+
+				go func(message kafka.Message) {
+					defer func() {
+						<-parallelismLimiter
+						kafkaConsumeInFlight.Dec()
+					}()
+
+					handleKafkaMessage(config, reader, state, message)
+				}(message)
+			}
+		}()
+	}
+}
+
+// handleKafkaMessage decodes and processes a single fetched message, retrying transient
+// failures with backoff before routing to the dead-letter topic, then commits the
+// message's offset (when KafkaCommitMode is "manual") so it is never redelivered once
+// handled one way or the other.
+func handleKafkaMessage(config *Config, reader *kafka.Reader, state *readerCommitState, message kafka.Message) {
+	ctx := contextWithRequestID(context.Background(), kafkaRequestID(message.Headers))
+	logger := FromContext(ctx)
+
+	var kafkaMsg KafkaMessage
+	if err := json.Unmarshal(message.Value, &kafkaMsg); err != nil {
+		logger.WithError(err).Error("Error unmarshaling Kafka message")
+		kafkaMessagesConsumedTotal.WithLabelValues("item-events", "error").Inc()
+		sendToDeadLetterTopic(ctx, config, message, err)
+		commitKafkaMessage(config, reader, state, message)
+		return
+	}
+
+	logger.Infof("Received Kafka message: %s - %s", kafkaMsg.Event, kafkaMsg.Timestamp)
+
+	var err error
+	for attempt := 1; attempt <= kafkaMaxProcessAttempts; attempt++ {
+		if err = processKafkaMessage(ctx, kafkaMsg); err == nil {
+			break
+		}
+		logger.WithError(err).Warnf("Error processing Kafka message (attempt %d/%d)", attempt, kafkaMaxProcessAttempts)
+		time.Sleep(backoffDuration(attempt))
+	}
+
+	if err != nil {
+		kafkaMessagesConsumedTotal.WithLabelValues("item-events", "error").Inc()
+		sendToDeadLetterTopic(ctx, config, message, err)
+	} else {
+		kafkaMessagesConsumedTotal.WithLabelValues("item-events", "success").Inc()
+	}
+
+	commitKafkaMessage(config, reader, state, message)
+}
+
+// commitKafkaMessage commits the message's offset in "manual" commit mode, but only
+// once state confirms every earlier-fetched offset on the same partition has also
+// finished, so a crash can never skip a still-in-flight lower offset. In "auto" mode the
+// reader's CommitInterval (set in connectKafka) handles committing on its own, so this
+// is a no-op.
+func commitKafkaMessage(config *Config, reader *kafka.Reader, state *readerCommitState, message kafka.Message) {
+	if config.KafkaCommitMode != "manual" {
+		return
+	}
+
+	commitOffset, ok := state.trackerFor(message.Partition).complete(message.Offset)
+	if !ok {
+		return
+	}
+
+	commitMessage := kafka.Message{Topic: message.Topic, Partition: message.Partition, Offset: commitOffset}
+	if err := reader.CommitMessages(context.Background(), commitMessage); err != nil {
+		baseLogger.WithError(err).Error("Error committing Kafka offset")
+	}
+}
+
+// sendToDeadLetterTopic republishes a message that exhausted its processing attempts, or
+// that could not be decoded at all, to config.KafkaDLTTopic so a poison-pill message doesn't block the partition forever.
+func sendToDeadLetterTopic(ctx context.Context, config *Config, message kafka.Message, cause error) {
+	err := dltKafkaWriter.WriteMessages(context.Background(), kafka.Message{
+		Topic: config.KafkaDLTTopic,
+		Key:   message.Key,
+		Value: message.Value,
+		Headers: append(message.Headers, kafka.Header{
+			Key:   "x-dlt-reason",
+			Value: []byte(cause.Error()),
+		}),
+	})
+	if err != nil {
+		FromContext(ctx).WithError(err).Errorf("Error sending message to dead-letter topic %s", config.KafkaDLTTopic)
+		return
+	}
+	kafkaMessagesProducedTotal.WithLabelValues(config.KafkaDLTTopic, "success").Inc()
+}
+
+// backoffDuration returns a linear backoff delay (200ms per attempt) for the given attempt number (1-based).
+func backoffDuration(attempt int) time.Duration {
+	return time.Duration(attempt) * 200 * time.Millisecond
+}
+
+// processKafkaMessage evaluates msg against active subscription jobs and republishes a
+// processed acknowledgement to Kafka. Returns an error so the caller can retry or route
+// to the dead-letter topic.
+func processKafkaMessage(ctx context.Context, msg KafkaMessage) error {
+	infoTypeID := msg.InfoTypeID
+	if infoTypeID == "" {
+		infoTypeID = msg.Event
+	}
+	dispatchToJobs(infoTypeID, msg.Data)
+
+	response := map[string]interface{}{
+		"original_event": msg.Event,
+		"processed_by":   "go-service",
+		"timestamp":      time.Now().Format(time.RFC3339),
+		"status":         "processed",
+	}
+
+	responseBytes, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+
+	if err := kafkaWriter.WriteMessages(context.Background(), kafka.Message{
+		Value:   responseBytes,
+		Headers: []kafka.Header{{Key: requestIDHeader, Value: []byte(requestIDFromContext(ctx))}},
+	}); err != nil {
+		kafkaMessagesProducedTotal.WithLabelValues("go-events", "error").Inc()
+		return err
+	}
+
+	kafkaMessagesProducedTotal.WithLabelValues("go-events", "success").Inc()
+	return nil
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoffDuration(t *testing.T) {
+	cases := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 1, want: 200 * time.Millisecond},
+		{attempt: 2, want: 400 * time.Millisecond},
+		{attempt: 3, want: 600 * time.Millisecond},
+	}
+
+	for _, tc := range cases {
+		if got := backoffDuration(tc.attempt); got != tc.want {
+			t.Errorf("backoffDuration(%d) = %v, want %v", tc.attempt, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
+	"github.com/streadway/amqp"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// requestIDHeader is the header (and Kafka/Rabbit message header) used to correlate a
+// request across the HTTP, Kafka and RabbitMQ legs of the pipeline.
+const requestIDHeader = "X-Request-ID"
+
+type loggerContextKey struct{}
+type requestIDContextKey struct{}
+
+// baseLogger is configured once, from Config, by initLogger.
+var baseLogger = logrus.New()
+
+// initLogger configures baseLogger's format and level from LOG_FORMAT/LOG_LEVEL.
+func initLogger(config *Config) {
+	switch strings.ToLower(config.LogFormat) {
+	case "json":
+		baseLogger.SetFormatter(&logrus.JSONFormatter{})
+	default:
+		// logrus's TextFormatter already renders as logfmt-style key=value pairs, so
+		// "text" and "logfmt" share a formatter.
+		baseLogger.SetFormatter(&logrus.TextFormatter{FullTimestamp: true})
+	}
+
+	level, err := logrus.ParseLevel(config.LogLevel)
+	if err != nil {
+		level = logrus.InfoLevel
+	}
+	baseLogger.SetLevel(level)
+}
+
+// WithContext attaches entry to ctx so a later FromContext(ctx) call returns it.
+func WithContext(ctx context.Context, entry *logrus.Entry) context.Context {
+	return context.WithValue(ctx, loggerContextKey{}, entry)
+}
+
+// FromContext returns the logger entry attached to ctx, or a bare entry on baseLogger
+// if none was attached (e.g. in code paths not reachable from a request or message).
+func FromContext(ctx context.Context) *logrus.Entry {
+	if entry, ok := ctx.Value(loggerContextKey{}).(*logrus.Entry); ok {
+		return entry
+	}
+	return logrus.NewEntry(baseLogger)
+}
+
+// requestIDFromContext returns the correlation ID attached to ctx, or "" if none.
+func requestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDContextKey{}).(string)
+	return id
+}
+
+// contextWithRequestID returns a context carrying requestID and a logger entry tagged
+// with it, so processKafkaMessage, processRabbitMessage, processNotification and every
+// Gin handler log under the same correlation ID. An empty requestID is replaced with a
+// freshly generated one, so callers can pass through whatever they extracted from an
+// inbound message header without checking it first.
+func contextWithRequestID(ctx context.Context, requestID string) context.Context {
+	if requestID == "" {
+		requestID = newRequestID()
+	}
+	ctx = context.WithValue(ctx, requestIDContextKey{}, requestID)
+	return WithContext(ctx, baseLogger.WithField("request_id", requestID))
+}
+
+// newRequestID generates a correlation ID, reusing the ObjectID generator already used
+// elsewhere in the service for ID values.
+func newRequestID() string {
+	return primitive.NewObjectID().Hex()
+}
+
+// amqpRequestID extracts the correlation ID from an inbound AMQP delivery's headers, or
+// "" if the publisher didn't set one.
+func amqpRequestID(headers amqp.Table) string {
+	if headers == nil {
+		return ""
+	}
+	if id, ok := headers[requestIDHeader].(string); ok {
+		return id
+	}
+	return ""
+}
+
+// kafkaRequestID extracts the correlation ID from an inbound Kafka message's headers, or
+// "" if the producer didn't set one.
+func kafkaRequestID(headers []kafka.Header) string {
+	for _, h := range headers {
+		if h.Key == requestIDHeader {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+// requestIDMiddleware extracts X-Request-ID from the incoming request, generating one
+// if absent, echoes it back on the response, and attaches it (plus a tagged logger) to
+// the request context.
+func requestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(requestIDHeader)
+		if requestID == "" {
+			requestID = newRequestID()
+		}
+		c.Header(requestIDHeader, requestID)
+
+		ctx := contextWithRequestID(c.Request.Context(), requestID)
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"net/http/pprof"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Prometheus metrics
+var (
+	httpRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "Total number of HTTP requests processed, labeled by route, method and status",
+	}, []string{"route", "method", "status"})
+
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP request latency distribution",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"route", "method"})
+
+	kafkaMessagesConsumedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "kafka_messages_consumed_total",
+		Help: "Total number of Kafka messages consumed, labeled by topic and outcome",
+	}, []string{"topic", "status"})
+
+	kafkaMessagesProducedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "kafka_messages_produced_total",
+		Help: "Total number of Kafka messages produced, labeled by topic and outcome",
+	}, []string{"topic", "status"})
+
+	kafkaConsumeInFlight = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "kafka_consume_in_flight",
+		Help: "Number of Kafka messages currently being processed",
+	})
+
+	rabbitPublishDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "rabbit_publish_duration_seconds",
+		Help:    "Latency of RabbitMQ publish calls",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"queue", "status"})
+
+	rabbitMessagesConsumedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "rabbit_messages_consumed_total",
+		Help: "Total number of RabbitMQ messages consumed, labeled by queue and outcome",
+	}, []string{"queue", "status"})
+
+	mongoOpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "mongo_op_duration_seconds",
+		Help:    "Latency of MongoDB operations",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"collection", "op"})
+)
+
+// observeMongoOp times a MongoDB operation and records it against mongoOpDuration.
+func observeMongoOp(collection, op string, fn func() error) error {
+	start := time.Now()
+	err := fn()
+	mongoOpDuration.WithLabelValues(collection, op).Observe(time.Since(start).Seconds())
+	return err
+}
+
+// metricsMiddleware records request counts and latency for every Gin route.
+func metricsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		httpRequestDuration.WithLabelValues(route, c.Request.Method).Observe(time.Since(start).Seconds())
+		httpRequestsTotal.WithLabelValues(route, c.Request.Method, strconv.Itoa(c.Writer.Status())).Inc()
+	}
+}
+
+// startMetricsServer exposes /metrics and, when enabled, /debug/pprof/* on their own listener.
+// Running it separately from the main API router keeps scrape/profiling traffic off the
+// application's request path.
+func startMetricsServer(config *Config) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	if config.PprofEnabled {
+		mux.HandleFunc("/debug/pprof/", pprof.Index)
+		mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+		mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+		mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+		mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+		log.Println("pprof endpoints enabled under /debug/pprof")
+	}
+
+	server := &http.Server{
+		Addr:    ":" + config.PrometheusPort,
+		Handler: mux,
+	}
+
+	go func() {
+		log.Printf("Metrics server listening on port %s", config.PrometheusPort)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Printf("Metrics server stopped: %v", err)
+		}
+	}()
+
+	return server
+}
+
+// shutdownMetricsServer gracefully stops the metrics listener, if one was started.
+func shutdownMetricsServer(ctx context.Context, server *http.Server) {
+	if server == nil {
+		return
+	}
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("Metrics server forced to shutdown: %v", err)
+	}
+}